@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"dagger/forge/internal/dagger"
+	"dagger/forge/internal/semver"
+)
+
+// conventionalCommit is a parsed Conventional Commits 1.0.0 subject line.
+type conventionalCommit struct {
+	Type     string // feat, fix, perf, refactor, chore, ...
+	Scope    string
+	Breaking bool
+	Subject  string
+}
+
+var conventionalSubjectRe = regexp.MustCompile(`^(\w+)(\(([^)]+)\))?(!)?:\s*(.+)$`)
+
+// parseConventionalCommit parses a commit's subject and body, reporting
+// ok=false if the subject doesn't follow the Conventional Commits format.
+func parseConventionalCommit(subject, body string) (commit conventionalCommit, ok bool) {
+	m := conventionalSubjectRe.FindStringSubmatch(subject)
+	if m == nil {
+		return conventionalCommit{}, false
+	}
+
+	return conventionalCommit{
+		Type:     strings.ToLower(m[1]),
+		Scope:    m[3],
+		Breaking: m[4] == "!" || strings.Contains(body, "BREAKING CHANGE:"),
+		Subject:  m[5],
+	}, true
+}
+
+// bumpRelevant reports whether a commit should influence the next version
+// or appear in the changelog: a recognized semver-relevant type, or any
+// breaking change regardless of type.
+func (c conventionalCommit) bumpRelevant() bool {
+	if c.Breaking {
+		return true
+	}
+	switch c.Type {
+	case "feat", "fix", "perf", "refactor":
+		return true
+	default:
+		return false
+	}
+}
+
+// commitSeparator and fieldSeparator delimit git log records/fields using
+// bytes that won't appear in commit text.
+const (
+	commitSeparator = "\x1e"
+	fieldSeparator  = "\x1f"
+)
+
+// conventionalCommitsSince returns the relevant Conventional Commits in
+// base..HEAD, parsed from the git history mounted at source.
+func (m *Forge) conventionalCommitsSince(ctx context.Context, source *dagger.Directory, base string) ([]conventionalCommit, error) {
+	log, err := dag.Container().
+		From("alpine/git:latest").
+		WithMountedDirectory("/repo", source).
+		WithWorkdir("/repo").
+		WithExec([]string{
+			"git", "log", base + "..HEAD",
+			"--format=%s" + fieldSeparator + "%b" + commitSeparator,
+		}).
+		Stdout(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit history since %s: %w", base, err)
+	}
+
+	var commits []conventionalCommit
+	for _, record := range strings.Split(log, commitSeparator) {
+		record = strings.TrimPrefix(record, "\n")
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, fieldSeparator, 2)
+		subject := fields[0]
+		var body string
+		if len(fields) > 1 {
+			body = fields[1]
+		}
+
+		commit, ok := parseConventionalCommit(subject, body)
+		if !ok || !commit.bumpRelevant() {
+			continue
+		}
+		commits = append(commits, commit)
+	}
+
+	return commits, nil
+}
+
+// bumpLevelForCommits computes the SemVer bump level implied by commits:
+// any breaking change is major, any feat is minor, otherwise patch.
+func bumpLevelForCommits(commits []conventionalCommit) semver.Level {
+	level := semver.Patch
+	for _, c := range commits {
+		if c.Breaking {
+			return semver.Major
+		}
+		if c.Type == "feat" {
+			level = semver.Minor
+		}
+	}
+	return level
+}
+
+// versionAndChangelogFromCommits resolves the next version from
+// Conventional Commits history in base..HEAD, along with a changelog
+// describing the relevant commits. changelog is "" when there are no
+// relevant commits (version is then just the current version, unchanged).
+// This is the single source of truth for both NextVersionFromCommits and
+// Release's --mode=conventional path, so the two can't diverge.
+func (m *Forge) versionAndChangelogFromCommits(ctx context.Context, source *dagger.Directory, base string) (version, changelog string, err error) {
+	currentVersion, err := m.Version(ctx, source)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get current version: %w", err)
+	}
+
+	commits, err := m.conventionalCommitsSince(ctx, source, base)
+	if err != nil {
+		return "", "", err
+	}
+	if len(commits) == 0 {
+		return currentVersion, "", nil
+	}
+
+	current, err := semver.Parse(currentVersion)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse current version: %w", err)
+	}
+
+	next, err := current.Bump(bumpLevelForCommits(commits), "")
+	if err != nil {
+		return "", "", err
+	}
+
+	return next.String(), conventionalChangelog(commits), nil
+}
+
+// NextVersionFromCommits derives the next version from Conventional Commits
+// history in base..HEAD: any breaking change bumps major, any feat bumps
+// minor, otherwise patch. It's a no-op (returns the current version
+// unchanged) if there are no relevant commits.
+func (m *Forge) NextVersionFromCommits(
+	ctx context.Context,
+	source *dagger.Directory,
+	// Base ref to diff from (exclusive); commits in base..HEAD are considered
+	// +optional
+	// +default="HEAD~1"
+	base string,
+) (string, error) {
+	if base == "" {
+		base = "HEAD~1"
+	}
+
+	version, _, err := m.versionAndChangelogFromCommits(ctx, source, base)
+	return version, err
+}
+
+// conventionalChangelogGroups orders the changelog sections emitted by
+// conventionalChangelog.
+var conventionalChangelogGroups = []struct {
+	title string
+	types map[string]bool
+}{
+	{"Features", map[string]bool{"feat": true}},
+	{"Fixes", map[string]bool{"fix": true}},
+	{"Performance", map[string]bool{"perf": true}},
+	{"Refactors", map[string]bool{"refactor": true}},
+}
+
+// conventionalChangelog renders commits as a Markdown changelog grouped by
+// type, with breaking changes called out in their own leading section.
+func conventionalChangelog(commits []conventionalCommit) string {
+	var sb strings.Builder
+
+	writeGroup := func(title string, entries []conventionalCommit) {
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "## %s\n", title)
+		for _, c := range entries {
+			fmt.Fprintf(&sb, "- %s\n", conventionalChangelogEntry(c))
+		}
+		sb.WriteString("\n")
+	}
+
+	var breaking []conventionalCommit
+	for _, c := range commits {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	writeGroup("Breaking Changes", breaking)
+
+	for _, group := range conventionalChangelogGroups {
+		var entries []conventionalCommit
+		for _, c := range commits {
+			if !c.Breaking && group.types[c.Type] {
+				entries = append(entries, c)
+			}
+		}
+		writeGroup(group.title, entries)
+	}
+
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+// conventionalChangelogEntry formats a single changelog line.
+func conventionalChangelogEntry(c conventionalCommit) string {
+	if c.Scope != "" {
+		return fmt.Sprintf("**%s:** %s", c.Scope, c.Subject)
+	}
+	return c.Subject
+}
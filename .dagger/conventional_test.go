@@ -0,0 +1,131 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"dagger/forge/internal/semver"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	cases := []struct {
+		subject string
+		body    string
+		want    conventionalCommit
+		wantOK  bool
+	}{
+		{
+			subject: "feat: add widget",
+			want:    conventionalCommit{Type: "feat", Subject: "add widget"},
+			wantOK:  true,
+		},
+		{
+			subject: "fix(parser): handle empty input",
+			want:    conventionalCommit{Type: "fix", Scope: "parser", Subject: "handle empty input"},
+			wantOK:  true,
+		},
+		{
+			subject: "feat!: drop legacy API",
+			want:    conventionalCommit{Type: "feat", Breaking: true, Subject: "drop legacy API"},
+			wantOK:  true,
+		},
+		{
+			subject: "feat(api): rework auth",
+			body:    "BREAKING CHANGE: tokens are no longer accepted",
+			want:    conventionalCommit{Type: "feat", Scope: "api", Breaking: true, Subject: "rework auth"},
+			wantOK:  true,
+		},
+		{
+			subject: "FIX: normalize case",
+			want:    conventionalCommit{Type: "fix", Subject: "normalize case"},
+			wantOK:  true,
+		},
+		{
+			subject: "not a conventional commit",
+			wantOK:  false,
+		},
+	}
+
+	for _, c := range cases {
+		got, ok := parseConventionalCommit(c.subject, c.body)
+		if ok != c.wantOK {
+			t.Errorf("parseConventionalCommit(%q) ok = %v, want %v", c.subject, ok, c.wantOK)
+			continue
+		}
+		if ok && got != c.want {
+			t.Errorf("parseConventionalCommit(%q) = %+v, want %+v", c.subject, got, c.want)
+		}
+	}
+}
+
+func TestBumpRelevant(t *testing.T) {
+	cases := []struct {
+		commit conventionalCommit
+		want   bool
+	}{
+		{conventionalCommit{Type: "feat"}, true},
+		{conventionalCommit{Type: "fix"}, true},
+		{conventionalCommit{Type: "perf"}, true},
+		{conventionalCommit{Type: "refactor"}, true},
+		{conventionalCommit{Type: "chore"}, false},
+		{conventionalCommit{Type: "docs"}, false},
+		{conventionalCommit{Type: "chore", Breaking: true}, true},
+	}
+
+	for _, c := range cases {
+		if got := c.commit.bumpRelevant(); got != c.want {
+			t.Errorf("%+v.bumpRelevant() = %v, want %v", c.commit, got, c.want)
+		}
+	}
+}
+
+func TestBumpLevelForCommits(t *testing.T) {
+	cases := []struct {
+		name    string
+		commits []conventionalCommit
+		want    semver.Level
+	}{
+		{"empty", nil, semver.Patch},
+		{"fix only", []conventionalCommit{{Type: "fix"}}, semver.Patch},
+		{"feat bumps minor", []conventionalCommit{{Type: "fix"}, {Type: "feat"}}, semver.Minor},
+		{"breaking bumps major", []conventionalCommit{{Type: "feat"}, {Type: "fix", Breaking: true}}, semver.Major},
+		{"breaking wins over everything", []conventionalCommit{{Breaking: true}, {Type: "feat"}}, semver.Major},
+	}
+
+	for _, c := range cases {
+		if got := bumpLevelForCommits(c.commits); got != c.want {
+			t.Errorf("%s: bumpLevelForCommits() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestConventionalChangelog(t *testing.T) {
+	commits := []conventionalCommit{
+		{Type: "feat", Scope: "api", Subject: "add search endpoint"},
+		{Type: "fix", Subject: "correct off-by-one"},
+		{Type: "feat", Breaking: true, Subject: "remove v1 API"},
+		{Type: "chore", Subject: "bump deps"},
+	}
+
+	got := conventionalChangelog(commits)
+
+	wantSections := []string{
+		"## Breaking Changes\n- remove v1 API",
+		"## Features\n- **api:** add search endpoint",
+		"## Fixes\n- correct off-by-one",
+	}
+	for _, want := range wantSections {
+		if !strings.Contains(got, want) {
+			t.Errorf("conventionalChangelog() missing section %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "chore") || strings.Contains(got, "bump deps") {
+		t.Errorf("conventionalChangelog() should omit non-relevant commits, got:\n%s", got)
+	}
+}
+
+func TestConventionalChangelogEmpty(t *testing.T) {
+	if got := conventionalChangelog(nil); got != "\n" {
+		t.Errorf("conventionalChangelog(nil) = %q, want %q", got, "\n")
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"dagger/forge/internal/dagger"
+)
+
+// distContainer is a minimal container with tar/gzip and sha256sum for
+// assembling and checksumming release artifacts. Alpine's default /bin/tar
+// is the BusyBox applet, which doesn't support the GNU-style flags
+// distTarball relies on for reproducibility, so the real GNU tar package is
+// installed explicitly rather than relying on whatever BusyBox ships.
+func (m *Forge) distContainer() *dagger.Container {
+	return dag.Container().
+		From("alpine:latest").
+		WithExec([]string{"apk", "add", "--no-cache", "tar"})
+}
+
+// distName returns the base name (without extension) for a dist artifact.
+func distName(t crossTarget, version string) string {
+	return fmt.Sprintf("forge-%s-%s%s-%s", t.OS, t.Arch, t.Suffix, version)
+}
+
+// distStage assembles the directory layout for a single target's release:
+// the target's binary alongside README/LICENSE/CHANGELOG from source.
+func (m *Forge) distStage(ctx context.Context, source *dagger.Directory, t crossTarget, name string) *dagger.Directory {
+	binary := m.BuildRelease(ctx, source, t.Triple)
+
+	return dag.Directory().
+		WithFile(name+"/"+t.binaryName(), binary, dagger.DirectoryWithFileOpts{Permissions: 0o755}).
+		WithFile(name+"/README.md", source.File("README.md")).
+		WithFile(name+"/LICENSE", source.File("LICENSE")).
+		WithFile(name+"/CHANGELOG.md", source.File("CHANGELOG.md"))
+}
+
+// distTarball packs stage (a directory containing exactly one top-level
+// "name" directory) into a deterministic name.tar.gz: sorted entries,
+// zeroed owner/group, and a fixed mtime so the archive is reproducible
+// across runs.
+func (m *Forge) distTarball(stage *dagger.Directory, name string) *dagger.File {
+	archive := name + ".tar.gz"
+
+	return m.distContainer().
+		WithMountedDirectory("/dist", stage).
+		WithWorkdir("/dist").
+		WithExec([]string{
+			"tar",
+			"--sort=name",
+			"--mtime=1970-01-01 00:00:00 UTC",
+			"--owner=0", "--group=0", "--numeric-owner",
+			"-czf", "/" + archive, name,
+		}).
+		File("/" + archive)
+}
+
+// Dist builds a reproducible, checksummed release tarball for a single
+// target: the forge binary plus README.md, LICENSE, and CHANGELOG.md from
+// source, packed as <name>.tar.gz with a sibling SHA256SUMS.
+func (m *Forge) Dist(
+	ctx context.Context,
+	source *dagger.Directory,
+	// Target triple to build and package (e.g. x86_64-unknown-linux-gnu)
+	// +optional
+	// +default="x86_64-unknown-linux-gnu"
+	target string,
+	// Version to embed in the artifact name (optional, defaults to
+	// NextVersion's auto-detected version). Callers that have already
+	// resolved a version, e.g. Release, should pass it explicitly so the
+	// tarball name can't diverge from the release it's attached to.
+	// +optional
+	version string,
+) (*dagger.Directory, error) {
+	if target == "" {
+		target = "x86_64-unknown-linux-gnu"
+	}
+	t, ok := lookupCrossTarget(target)
+	if !ok {
+		return nil, fmt.Errorf("unsupported target %q", target)
+	}
+	t.Triple = target
+
+	if version == "" {
+		var err error
+		version, err = m.NextVersion(ctx, source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine version: %w", err)
+		}
+	}
+
+	name := distName(t, version)
+	archive := name + ".tar.gz"
+	tarball := m.distTarball(m.distStage(ctx, source, t, name), name)
+
+	sums, err := m.sha256Sums(ctx, map[string]*dagger.File{archive: tarball})
+	if err != nil {
+		return nil, err
+	}
+
+	return dag.Directory().
+		WithFile(archive, tarball).
+		WithNewFile("SHA256SUMS", sums), nil
+}
+
+// DistAll builds Dist's reproducible tarball for every target in
+// crossTargets, collecting them alongside a single SHA256SUMS that
+// covers every tarball.
+func (m *Forge) DistAll(
+	ctx context.Context,
+	source *dagger.Directory,
+	// Version to embed in each artifact name (optional, defaults to
+	// NextVersion's auto-detected version). Callers that have already
+	// resolved a version, e.g. Release, should pass it explicitly so the
+	// tarballs can't diverge from the release they're attached to.
+	// +optional
+	version string,
+) (*dagger.Directory, error) {
+	if version == "" {
+		var err error
+		version, err = m.NextVersion(ctx, source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine version: %w", err)
+		}
+	}
+
+	artifacts := make(map[string]*dagger.File, len(crossTargets))
+	out := dag.Directory()
+	for _, t := range crossTargets {
+		name := distName(t, version)
+		archive := name + ".tar.gz"
+		tarball := m.distTarball(m.distStage(ctx, source, t, name), name)
+
+		out = out.WithFile(archive, tarball)
+		artifacts[archive] = tarball
+	}
+
+	sums, err := m.sha256Sums(ctx, artifacts)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.WithNewFile("SHA256SUMS", sums), nil
+}
+
+// sha256Sums computes a `sha256sum`-formatted manifest covering artifacts,
+// keyed by the filename each should appear under in the manifest.
+func (m *Forge) sha256Sums(ctx context.Context, artifacts map[string]*dagger.File) (string, error) {
+	container := m.distContainer().WithWorkdir("/sums")
+
+	names := make([]string, 0, len(artifacts))
+	for name, file := range artifacts {
+		container = container.WithMountedFile("/sums/"+name, file)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cmd := append([]string{"sha256sum"}, names...)
+	return container.WithExec(cmd).Stdout(ctx)
+}
@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestCrossTargetFilename(t *testing.T) {
+	cases := []struct {
+		target crossTarget
+		want   string
+	}{
+		{crossTarget{OS: "linux", Arch: "amd64"}, "forge-linux-amd64"},
+		{crossTarget{OS: "linux", Arch: "arm64", Suffix: "-musl"}, "forge-linux-arm64-musl"},
+		{crossTarget{OS: "windows", Arch: "amd64", Ext: ".exe"}, "forge-windows-amd64.exe"},
+	}
+
+	for _, c := range cases {
+		if got := c.target.filename(); got != c.want {
+			t.Errorf("filename() = %q, want %q", got, c.want)
+		}
+	}
+}
+
+func TestCrossTargetBinaryName(t *testing.T) {
+	cases := []struct {
+		target crossTarget
+		want   string
+	}{
+		{crossTarget{OS: "linux", Arch: "amd64"}, "forge"},
+		{crossTarget{OS: "windows", Arch: "amd64", Ext: ".exe"}, "forge.exe"},
+	}
+
+	for _, c := range cases {
+		if got := c.target.binaryName(); got != c.want {
+			t.Errorf("binaryName() = %q, want %q", got, c.want)
+		}
+	}
+}
+
+func TestLookupCrossTarget(t *testing.T) {
+	got, ok := lookupCrossTarget("aarch64-apple-darwin")
+	if !ok {
+		t.Fatalf("lookupCrossTarget(aarch64-apple-darwin) ok = false, want true")
+	}
+	want := crossTarget{Triple: "aarch64-apple-darwin", OS: "darwin", Arch: "arm64", Zig: true}
+	if got != want {
+		t.Errorf("lookupCrossTarget(aarch64-apple-darwin) = %+v, want %+v", got, want)
+	}
+
+	if _, ok := lookupCrossTarget("sparc-unknown-none"); ok {
+		t.Errorf("lookupCrossTarget(sparc-unknown-none) ok = true, want false")
+	}
+}
+
+func TestDistName(t *testing.T) {
+	cases := []struct {
+		target crossTarget
+		want   string
+	}{
+		{crossTarget{OS: "linux", Arch: "amd64"}, "forge-linux-amd64-1.2.3"},
+		{crossTarget{OS: "linux", Arch: "arm64", Suffix: "-musl"}, "forge-linux-arm64-musl-1.2.3"},
+	}
+
+	for _, c := range cases {
+		if got := distName(c.target, "1.2.3"); got != c.want {
+			t.Errorf("distName() = %q, want %q", got, c.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package semver implements the minimal subset of SemVer 2.0.0 parsing and
+// bumping that forge's release tooling needs: a numeric MAJOR.MINOR.PATCH
+// core plus optional prerelease and build metadata.
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Level is a SemVer core component to bump.
+type Level string
+
+const (
+	Major Level = "major"
+	Minor Level = "minor"
+	Patch Level = "patch"
+)
+
+// Version is a parsed SemVer 2.0.0 version.
+type Version struct {
+	Major, Minor, Patch uint64
+	Pre                 string
+	Build               string
+}
+
+// Parse parses a SemVer string, tolerating a leading "v".
+func Parse(s string) (Version, error) {
+	trimmed := strings.TrimPrefix(s, "v")
+
+	core := trimmed
+	var pre, build string
+	if i := strings.IndexByte(core, '+'); i >= 0 {
+		build = core[i+1:]
+		core = core[:i]
+	}
+	if i := strings.IndexByte(core, '-'); i >= 0 {
+		pre = core[i+1:]
+		core = core[:i]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid semver %q: expected MAJOR.MINOR.PATCH", s)
+	}
+
+	var nums [3]uint64
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid semver %q: %w", s, err)
+		}
+		nums[i] = n
+	}
+
+	if pre != "" && !validIdentifiers(pre) {
+		return Version{}, fmt.Errorf("invalid semver %q: invalid prerelease %q", s, pre)
+	}
+	if build != "" && !validIdentifiers(build) {
+		return Version{}, fmt.Errorf("invalid semver %q: invalid build metadata %q", s, build)
+	}
+
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2], Pre: pre, Build: build}, nil
+}
+
+// validIdentifiers reports whether s is a dot-separated list of non-empty
+// alphanumeric-or-hyphen identifiers, per the SemVer prerelease/build grammar.
+func validIdentifiers(s string) bool {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return false
+		}
+		for _, r := range id {
+			switch {
+			case r >= '0' && r <= '9', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-':
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String renders the version as "MAJOR.MINOR.PATCH[-PRE][+BUILD]".
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Bump returns v advanced to the next version, with pre set as the
+// resulting prerelease identifier (empty for a final release).
+//
+// If v is already a prerelease, level is ignored: the numeric core is
+// left untouched and only the prerelease tag changes, so that a version
+// like "1.2.3-rc.1" moves to "1.2.3-rc.2" via Bump(_, "rc.2") or
+// finalizes to "1.2.3" via Bump(_, ""). Otherwise, level increments the
+// requested core component (resetting lower components to zero) and
+// pre seeds the new prerelease, if any.
+func (v Version) Bump(level Level, pre string) (Version, error) {
+	out := v
+	out.Build = ""
+
+	if v.Pre != "" {
+		out.Pre = pre
+		return out, nil
+	}
+
+	switch level {
+	case Major:
+		out.Major++
+		out.Minor = 0
+		out.Patch = 0
+	case Minor:
+		out.Minor++
+		out.Patch = 0
+	case Patch:
+		out.Patch++
+	default:
+		return Version{}, fmt.Errorf("invalid bump level %q: must be major, minor, or patch", level)
+	}
+	out.Pre = pre
+	return out, nil
+}
@@ -0,0 +1,98 @@
+package semver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    Version
+		wantErr bool
+	}{
+		{in: "1.2.3", want: Version{Major: 1, Minor: 2, Patch: 3}},
+		{in: "v1.2.3", want: Version{Major: 1, Minor: 2, Patch: 3}},
+		{in: "1.2.3-rc.1", want: Version{Major: 1, Minor: 2, Patch: 3, Pre: "rc.1"}},
+		{in: "1.2.3+build.5", want: Version{Major: 1, Minor: 2, Patch: 3, Build: "build.5"}},
+		{in: "1.2.3-rc.1+build.5", want: Version{Major: 1, Minor: 2, Patch: 3, Pre: "rc.1", Build: "build.5"}},
+		{in: "1.2", wantErr: true},
+		{in: "1.2.3.4", wantErr: true},
+		{in: "1.2.x", wantErr: true},
+	}
+
+	for _, c := range cases {
+		got, err := Parse(c.in)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) = %v, want error", c.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) unexpected error: %v", c.in, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestVersionString(t *testing.T) {
+	cases := []struct {
+		v    Version
+		want string
+	}{
+		{v: Version{Major: 1, Minor: 2, Patch: 3}, want: "1.2.3"},
+		{v: Version{Major: 1, Minor: 2, Patch: 3, Pre: "rc.1"}, want: "1.2.3-rc.1"},
+		{v: Version{Major: 1, Minor: 2, Patch: 3, Build: "build.5"}, want: "1.2.3+build.5"},
+		{v: Version{Major: 1, Minor: 2, Patch: 3, Pre: "rc.1", Build: "build.5"}, want: "1.2.3-rc.1+build.5"},
+	}
+
+	for _, c := range cases {
+		if got := c.v.String(); got != c.want {
+			t.Errorf("%+v.String() = %q, want %q", c.v, got, c.want)
+		}
+	}
+}
+
+func TestVersionBump(t *testing.T) {
+	cases := []struct {
+		name  string
+		v     Version
+		level Level
+		pre   string
+		want  string
+	}{
+		{name: "major", v: mustParse(t, "1.2.3"), level: Major, want: "2.0.0"},
+		{name: "minor", v: mustParse(t, "1.2.3"), level: Minor, want: "1.3.0"},
+		{name: "patch", v: mustParse(t, "1.2.3"), level: Patch, want: "1.2.4"},
+		{name: "seed prerelease", v: mustParse(t, "1.2.3"), level: Patch, pre: "rc.1", want: "1.2.4-rc.1"},
+		{name: "advance prerelease ignores level", v: mustParse(t, "1.2.3-rc.1"), level: Major, pre: "rc.2", want: "1.2.3-rc.2"},
+		{name: "finalize prerelease", v: mustParse(t, "1.2.3-rc.1"), level: Major, pre: "", want: "1.2.3"},
+		{name: "drops build metadata", v: mustParse(t, "1.2.3+build.5"), level: Patch, want: "1.2.4"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.v.Bump(c.level, c.pre)
+			if err != nil {
+				t.Fatalf("Bump(%q, %q) unexpected error: %v", c.level, c.pre, err)
+			}
+			if got.String() != c.want {
+				t.Errorf("Bump(%q, %q) = %q, want %q", c.level, c.pre, got.String(), c.want)
+			}
+		})
+	}
+
+	if _, err := mustParse(t, "1.2.3").Bump("bogus", ""); err == nil {
+		t.Error("Bump(\"bogus\", \"\") = nil error, want error")
+	}
+}
+
+func mustParse(t *testing.T, s string) Version {
+	t.Helper()
+	v, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q) unexpected error: %v", s, err)
+	}
+	return v
+}
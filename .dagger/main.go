@@ -15,18 +15,31 @@
 //   dagger call ci --source=.  # runs all checks
 //   dagger call version --source=.
 //   dagger call next-version --source=.  # shows version that will be released
+//   dagger call next-version-from-commits --source=.  # next version from Conventional Commits history
 //   dagger call version-changed --source=.  # check if version was bumped in commit
+//   dagger call bump-version --source=. --bump=minor export --path=.  # bump Cargo.toml(s) and write back
+//   dagger call build-release --source=. --target=aarch64-apple-darwin export --path=./forge
+//   dagger call build-all-targets --source=. export --path=./dist
+//   dagger call dist --source=. --target=x86_64-unknown-linux-gnu export --path=./dist
+//   dagger call dist-all --source=. export --path=./dist  # Dist for every cross target
+//   dagger call sbom --source=. --format=spdx-json export --path=./sbom.spdx.json
+//   dagger call binary-sbom --source=. --target=x86_64-unknown-linux-gnu export --path=./forge.spdx.json
+//   dagger call sign --image=ghcr.io/jedarden/forge@sha256:... --key=env:COSIGN_KEY
+//   dagger call attest --image=ghcr.io/jedarden/forge@sha256:... --sbom=./forge.spdx.json
 //   dagger call release --source=. --github-token=env:GITHUB_TOKEN
+//   dagger call release --source=. --github-token=env:GITHUB_TOKEN --mode=conventional
+//   dagger call release --source=. --github-token=env:GITHUB_TOKEN --registry=ghcr.io/jedarden/forge --registry-username=... --registry-password=env:REGISTRY_PASSWORD
 
 package main
 
 import (
 	"context"
 	"fmt"
-	"regexp"
+	"strconv"
 	"strings"
 
 	"dagger/forge/internal/dagger"
+	"dagger/forge/internal/semver"
 )
 
 type Forge struct{}
@@ -151,6 +164,13 @@ func (m *Forge) Publish(
 	username string,
 	// Registry password secret
 	password *dagger.Secret,
+	// Sign the pushed image with cosign and attach its SBOM
+	// +optional
+	// +default=false
+	sign bool,
+	// Cosign private key for key-based signing; omit for keyless OIDC
+	// +optional
+	cosignKey *dagger.Secret,
 ) (string, error) {
 	container := m.BuildContainer(ctx, source)
 
@@ -161,33 +181,85 @@ func (m *Forge) Publish(
 		return "", err
 	}
 
+	if sign {
+		if _, err := m.Sign(ctx, addr, cosignKey, username, password); err != nil {
+			return "", fmt.Errorf("failed to sign image: %w", err)
+		}
+
+		sbom, err := m.Sbom(ctx, source, "spdx-json")
+		if err != nil {
+			return "", fmt.Errorf("failed to generate sbom: %w", err)
+		}
+		if _, err := m.Attest(ctx, addr, sbom, "spdxjson", cosignKey, username, password); err != nil {
+			return "", fmt.Errorf("failed to attest sbom: %w", err)
+		}
+	}
+
 	return addr, nil
 }
 
 // Version extracts the semver version from Cargo.toml workspace
 func (m *Forge) Version(ctx context.Context, source *dagger.Directory) (string, error) {
-	// Read Cargo.toml and extract workspace version
-	cargoToml, err := source.File("Cargo.toml").Contents(ctx)
+	raw, err := m.ReadVersion(ctx, source)
 	if err != nil {
-		return "", fmt.Errorf("failed to read Cargo.toml: %w", err)
-	}
-
-	// Match workspace version: version = "x.y.z"
-	re := regexp.MustCompile(`\[workspace\.package\][\s\S]*?version\s*=\s*"([^"]+)"`)
-	matches := re.FindStringSubmatch(cargoToml)
-	if len(matches) < 2 {
-		// Fallback: try package version directly
-		re = regexp.MustCompile(`(?m)^version\s*=\s*"([^"]+)"`)
-		matches = re.FindStringSubmatch(cargoToml)
-		if len(matches) < 2 {
-			return "", fmt.Errorf("failed to extract version from Cargo.toml")
-		}
+		return "", err
+	}
+
+	v, err := semver.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid version in Cargo.toml: %w", err)
 	}
 
-	return matches[1], nil
+	return v.String(), nil
 }
 
-// crossContainer creates a Rust container with cross-compilation support
+// crossTarget describes how to build and name a release artifact for a
+// Rust target triple.
+type crossTarget struct {
+	Triple string
+	OS     string
+	Arch   string
+	Suffix string // filename suffix, e.g. "-musl"
+	Ext    string // filename extension, e.g. ".exe"
+	Zig    bool   // build via cargo-zigbuild instead of cargo build
+}
+
+// filename returns the release binary name for this target, e.g.
+// "forge-darwin-arm64" or "forge-windows-amd64.exe".
+func (t crossTarget) filename() string {
+	return fmt.Sprintf("forge-%s-%s%s%s", t.OS, t.Arch, t.Suffix, t.Ext)
+}
+
+// binaryName returns the filename cargo produces under target/<triple>/release.
+func (t crossTarget) binaryName() string {
+	return "forge" + t.Ext
+}
+
+// crossTargets lists every target triple BuildAllTargets and Dist produce
+// artifacts for.
+var crossTargets = []crossTarget{
+	{Triple: "x86_64-unknown-linux-gnu", OS: "linux", Arch: "amd64"},
+	{Triple: "aarch64-unknown-linux-gnu", OS: "linux", Arch: "arm64"},
+	{Triple: "aarch64-unknown-linux-musl", OS: "linux", Arch: "arm64", Suffix: "-musl", Zig: true},
+	{Triple: "x86_64-apple-darwin", OS: "darwin", Arch: "amd64", Zig: true},
+	{Triple: "aarch64-apple-darwin", OS: "darwin", Arch: "arm64", Zig: true},
+	{Triple: "x86_64-pc-windows-gnu", OS: "windows", Arch: "amd64", Ext: ".exe", Zig: true},
+}
+
+// lookupCrossTarget finds the crossTarget for a target triple.
+func lookupCrossTarget(triple string) (crossTarget, bool) {
+	for _, t := range crossTargets {
+		if t.Triple == triple {
+			return t, true
+		}
+	}
+	return crossTarget{}, false
+}
+
+// crossContainer creates a Rust container with cross-compilation support.
+// macOS, Windows, and musl targets build via cargo-zigbuild, since the Zig
+// toolchain bundles its own C cross-compilers and sysroots; the native
+// Linux gnu targets use plain cargo with apt-installed cross-gcc.
 func (m *Forge) crossContainer(source *dagger.Directory, target string) *dagger.Container {
 	container := dag.Container().
 		From("rust:1.83-slim").
@@ -198,25 +270,25 @@ func (m *Forge) crossContainer(source *dagger.Directory, target string) *dagger.
 		WithMountedCache("/app/target", dag.CacheVolume("forge-target-"+target)).
 		WithEnvVariable("CARGO_HOME", "/root/.cargo").
 		WithEnvVariable("CARGO_TERM_COLOR", "always").
-		WithEnvVariable("RUST_BACKTRACE", "1")
+		WithEnvVariable("RUST_BACKTRACE", "1").
+		WithExec([]string{"rustup", "target", "add", target})
+
+	if ct, ok := lookupCrossTarget(target); ok && ct.Zig {
+		container = container.
+			WithMountedCache("/root/.cache/cargo-zigbuild", dag.CacheVolume("cargo-zigbuild")).
+			WithExec([]string{"apt-get", "update"}).
+			WithExec([]string{"apt-get", "install", "-y", "python3-pip"}).
+			WithExec([]string{"pip3", "install", "--break-system-packages", "ziglang"}).
+			WithExec([]string{"cargo", "install", "cargo-zigbuild"})
+	}
 
 	// Install target-specific toolchains
 	switch target {
-	case "x86_64-unknown-linux-gnu":
-		// Default target, no extra setup needed
-		container = container.
-			WithExec([]string{"rustup", "target", "add", target})
 	case "aarch64-unknown-linux-gnu":
 		container = container.
 			WithExec([]string{"apt-get", "update"}).
 			WithExec([]string{"apt-get", "install", "-y", "gcc-aarch64-linux-gnu"}).
-			WithExec([]string{"rustup", "target", "add", target}).
 			WithEnvVariable("CARGO_TARGET_AARCH64_UNKNOWN_LINUX_GNU_LINKER", "aarch64-linux-gnu-gcc")
-	case "x86_64-apple-darwin", "aarch64-apple-darwin":
-		// macOS cross-compilation requires osxcross or building on macOS
-		// For now, we'll skip these in Dagger and rely on GitHub Actions matrix
-		container = container.
-			WithExec([]string{"rustup", "target", "add", target})
 	}
 
 	return container
@@ -237,36 +309,23 @@ func (m *Forge) BuildRelease(
 
 	container := m.crossContainer(source, target)
 
-	// Build for target
-	container = container.WithExec([]string{
-		"cargo", "build", "--release", "--target", target,
-	})
+	ct, zig := lookupCrossTarget(target)
+	buildCmd := []string{"cargo", "build", "--release", "--target", target}
+	if zig && ct.Zig {
+		buildCmd[1] = "zigbuild"
+	}
+	container = container.WithExec(buildCmd)
 
-	return container.File("/app/target/" + target + "/release/forge")
+	return container.File("/app/target/" + target + "/release/" + ct.binaryName())
 }
 
 // BuildAllTargets builds release binaries for all supported targets
 func (m *Forge) BuildAllTargets(ctx context.Context, source *dagger.Directory) *dagger.Directory {
-	targets := []string{
-		"x86_64-unknown-linux-gnu",
-		"aarch64-unknown-linux-gnu",
-	}
-
 	outputDir := dag.Directory()
 
-	for _, target := range targets {
-		binary := m.BuildRelease(ctx, source, target)
-
-		// Name binary with target suffix
-		parts := strings.Split(target, "-")
-		arch := parts[0]
-		os := "linux"
-		if strings.Contains(target, "apple") {
-			os = "darwin"
-		}
-
-		filename := fmt.Sprintf("forge-%s-%s", os, arch)
-		outputDir = outputDir.WithFile(filename, binary)
+	for _, t := range crossTargets {
+		binary := m.BuildRelease(ctx, source, t.Triple)
+		outputDir = outputDir.WithFile(t.filename(), binary)
 	}
 
 	return outputDir
@@ -300,22 +359,26 @@ func (m *Forge) VersionChanged(
 		return true, nil
 	}
 
-	// Extract version from previous Cargo.toml
-	re := regexp.MustCompile(`\[workspace\.package\][\s\S]*?version\s*=\s*"([^"]+)"`)
-	matches := re.FindStringSubmatch(prevCargoToml)
-	if len(matches) < 2 {
-		// Fallback: try package version directly
-		re = regexp.MustCompile(`(?m)^version\s*=\s*"([^"]+)"`)
-		matches = re.FindStringSubmatch(prevCargoToml)
-		if len(matches) < 2 {
-			// Couldn't parse previous version - treat as changed
-			return true, nil
-		}
+	// Parse the previous Cargo.toml through the same taplo-backed reader as
+	// Version, rather than re-deriving it with a regex, so whitespace,
+	// comments, or workspace-inherited versions don't misfire a change.
+	prevSource := dag.Directory().WithNewFile("Cargo.toml", prevCargoToml)
+	prevVersion, err := m.ReadVersion(ctx, prevSource)
+	if err != nil {
+		// Couldn't parse previous version - treat as changed
+		return true, nil
 	}
-	prevVersion := matches[1]
 
-	// Compare versions
-	return currentVersion != prevVersion, nil
+	current, err := semver.Parse(currentVersion)
+	if err != nil {
+		return true, nil
+	}
+	prev, err := semver.Parse(prevVersion)
+	if err != nil {
+		return true, nil
+	}
+
+	return current != prev, nil
 }
 
 // NextVersion returns the version to use for release
@@ -347,21 +410,49 @@ func (m *Forge) NextVersion(
 	return incrementVersion(version), nil
 }
 
-// incrementVersion bumps the patch version of a semver string
+// incrementVersion auto-advances a semver string when Cargo.toml wasn't
+// manually bumped: a prerelease's trailing numeric identifier is advanced
+// in place (e.g. "1.2.3-rc.1" -> "1.2.3-rc.2"), a prerelease without one
+// (e.g. "1.2.3-alpha") or an unparseable string is returned unchanged, and
+// a final release has its patch component bumped.
 func incrementVersion(version string) string {
-	// Remove v prefix if present
-	v := strings.TrimPrefix(version, "v")
-	parts := strings.Split(v, ".")
-	if len(parts) != 3 {
+	v, err := semver.Parse(version)
+	if err != nil {
 		return version
 	}
 
-	var major, minor, patch int
-	fmt.Sscanf(parts[0], "%d", &major)
-	fmt.Sscanf(parts[1], "%d", &minor)
-	fmt.Sscanf(parts[2], "%d", &patch)
+	if v.Pre != "" {
+		next, ok := bumpTrailingNumber(v.Pre)
+		if !ok {
+			// Non-numeric prerelease identifier (e.g. "alpha", "SNAPSHOT"):
+			// there's nothing to safely auto-advance, and Bump would
+			// silently finalize it, so leave the version untouched instead.
+			return version
+		}
+		v.Pre = next
+		v.Build = ""
+		return v.String()
+	}
 
-	return fmt.Sprintf("%d.%d.%d", major, minor, patch+1)
+	next, err := v.Bump(semver.Patch, "")
+	if err != nil {
+		return version
+	}
+	return next.String()
+}
+
+// bumpTrailingNumber increments the trailing dot-identifier of s if it's
+// numeric, e.g. "rc.1" -> "rc.2". ok is false if there's nothing numeric
+// to advance.
+func bumpTrailingNumber(s string) (result string, ok bool) {
+	parts := strings.Split(s, ".")
+	last := parts[len(parts)-1]
+	n, err := strconv.ParseUint(last, 10, 64)
+	if err != nil {
+		return "", false
+	}
+	parts[len(parts)-1] = strconv.FormatUint(n+1, 10)
+	return strings.Join(parts, "."), true
 }
 
 // Release creates a GitHub release with built binaries
@@ -386,22 +477,76 @@ func (m *Forge) Release(
 	// +optional
 	// +default=false
 	strict bool,
+	// Prerelease identifier to apply to the release version (e.g. "rc.1").
+	// Leave empty to release the resolved version as-is.
+	// +optional
+	pre string,
+	// Version resolution mode: "auto" (default, bump patch if Cargo.toml was
+	// unchanged), "strict" (exact Cargo.toml version), or "conventional"
+	// (derive the bump level, and release notes, from Conventional Commits
+	// history since base).
+	// +optional
+	// +default="auto"
+	mode string,
+	// Base ref for --mode=conventional's commit range (base..HEAD)
+	// +optional
+	// +default="HEAD~1"
+	base string,
+	// Container registry to also publish and sign an image to (e.g.
+	// "docker.io/username"). Leave empty to skip image publishing.
+	// +optional
+	registry string,
+	// Registry username, required alongside registryPassword if registry is set
+	// +optional
+	registryUsername string,
+	// Registry password secret, required alongside registryUsername if registry is set
+	// +optional
+	registryPassword *dagger.Secret,
+	// Sign the published image with cosign; only applies if registry is set
+	// +optional
+	// +default=true
+	sign bool,
+	// Cosign private key for key-based image signing; omit for keyless OIDC
+	// +optional
+	cosignKey *dagger.Secret,
 ) (string, error) {
+	if mode == "" {
+		mode = "auto"
+	}
+	if mode != "auto" && mode != "strict" && mode != "conventional" {
+		return "", fmt.Errorf("invalid mode %q: must be auto, strict, or conventional", mode)
+	}
+	if base == "" {
+		base = "HEAD~1"
+	}
+
 	var originalVersion string
+	var changelog string
 	var err error
 
 	// Get the version to use
-	if version != "" {
+	switch {
+	case version != "":
 		// Explicit version provided, use it
 		originalVersion = version
-	} else if strict {
+	case mode == "conventional":
+		originalVersion, err = m.Version(ctx, source)
+		if err != nil {
+			return "", fmt.Errorf("failed to get version: %w", err)
+		}
+
+		version, changelog, err = m.versionAndChangelogFromCommits(ctx, source, base)
+		if err != nil {
+			return "", err
+		}
+	case strict || mode == "strict":
 		// Strict mode: use exact Cargo.toml version
 		version, err = m.Version(ctx, source)
 		if err != nil {
 			return "", fmt.Errorf("failed to get version: %w", err)
 		}
 		originalVersion = version
-	} else {
+	default:
 		// Auto mode: check if version was changed in commit
 		originalVersion, err = m.Version(ctx, source)
 		if err != nil {
@@ -414,6 +559,16 @@ func (m *Forge) Release(
 		}
 	}
 
+	// Apply an explicit prerelease override, if requested
+	if pre != "" {
+		parsed, err := semver.Parse(version)
+		if err != nil {
+			return "", fmt.Errorf("invalid version %q: %w", version, err)
+		}
+		parsed.Pre = pre
+		version = parsed.String()
+	}
+
 	// Ensure version has v prefix for git tag
 	tag := version
 	if !strings.HasPrefix(tag, "v") {
@@ -426,8 +581,34 @@ func (m *Forge) Release(
 		versionNote = fmt.Sprintf(" (auto-incremented from %s)", originalVersion)
 	}
 
-	// Build all target binaries
+	// Build all target binaries, plus the checksummed dist tarballs
+	// downstream consumers can verify and extract directly.
 	binaries := m.BuildAllTargets(ctx, source)
+	dist, err := m.DistAll(ctx, source, version)
+	if err != nil {
+		return "", fmt.Errorf("failed to build dist tarballs: %w", err)
+	}
+
+	// Per-target SBOM sidecar for each released binary
+	sboms, err := m.BinarySbomsAll(ctx, source, "spdx-json")
+	if err != nil {
+		return "", fmt.Errorf("failed to generate sboms: %w", err)
+	}
+
+	// Optionally publish, sign, and attest a container image alongside the
+	// binary release
+	imageNote := ""
+	if registry != "" {
+		addr, err := m.Publish(ctx, source, registry, tag, registryUsername, registryPassword, sign, cosignKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to publish image: %w", err)
+		}
+		if sign {
+			imageNote = fmt.Sprintf("\nPublished and signed image %s", addr)
+		} else {
+			imageNote = fmt.Sprintf("\nPublished image %s", addr)
+		}
+	}
 
 	// Create release using gh CLI
 	releaseContainer := dag.Container().
@@ -435,6 +616,8 @@ func (m *Forge) Release(
 		WithSecretVariable("GITHUB_TOKEN", githubToken).
 		WithWorkdir("/release").
 		WithMountedDirectory("/release/binaries", binaries).
+		WithMountedDirectory("/release/dist", dist).
+		WithMountedDirectory("/release/sbom", sboms).
 		WithMountedDirectory("/release/source", source)
 
 	// Build gh release command
@@ -442,7 +625,13 @@ func (m *Forge) Release(
 		"gh", "release", "create", tag,
 		"--repo", "jedarden/forge",
 		"--title", fmt.Sprintf("Forge %s", tag),
-		"--generate-notes",
+	}
+	if changelog != "" {
+		// Conventional-commit mode: use the synthesized changelog instead
+		// of GitHub's generic auto-generated notes.
+		releaseCmd = append(releaseCmd, "--notes", changelog)
+	} else {
+		releaseCmd = append(releaseCmd, "--generate-notes")
 	}
 
 	if draft {
@@ -452,8 +641,14 @@ func (m *Forge) Release(
 		releaseCmd = append(releaseCmd, "--prerelease")
 	}
 
-	// Add binary files
-	releaseCmd = append(releaseCmd, "/release/binaries/*")
+	// Add binary files, dist tarballs, their combined checksums, and each
+	// binary's SBOM sidecar
+	releaseCmd = append(releaseCmd,
+		"/release/binaries/*",
+		"/release/dist/*.tar.gz",
+		"/release/dist/SHA256SUMS",
+		"/release/sbom/*",
+	)
 
 	// Execute release creation
 	output, err := releaseContainer.
@@ -463,7 +658,7 @@ func (m *Forge) Release(
 		return "", fmt.Errorf("failed to create release: %w", err)
 	}
 
-	return fmt.Sprintf("Created release %s%s\n%s", tag, versionNote, output), nil
+	return fmt.Sprintf("Created release %s%s%s\n%s", tag, versionNote, imageNote, output), nil
 }
 
 // BumpVersion increments the version in Cargo.toml
@@ -473,55 +668,55 @@ func (m *Forge) BumpVersion(
 	// Version bump type: major, minor, or patch
 	// +default="patch"
 	bump string,
+	// Prerelease identifier for the bumped version (e.g. "rc.1").
+	// Leave empty to produce a final release version. If the current
+	// version is already a prerelease, bump is ignored and pre either
+	// advances it (e.g. "rc.2") or finalizes it (pre="").
+	// +optional
+	pre string,
+	// Which Cargo.toml(s) to bump: "workspace" (default, propagate to every
+	// workspace member) or "root" (only the workspace root manifest)
+	// +optional
+	// +default="workspace"
+	scope string,
 ) (*dagger.Directory, error) {
 	if bump == "" {
 		bump = "patch"
 	}
+	if scope == "" {
+		scope = "workspace"
+	}
+	if scope != "workspace" && scope != "root" {
+		return nil, fmt.Errorf("invalid scope %q: must be workspace or root", scope)
+	}
 
 	// Get current version
-	currentVersion, err := m.Version(ctx, source)
+	currentVersion, err := m.ReadVersion(ctx, source)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current version: %w", err)
 	}
 
-	// Parse and increment version
-	parts := strings.Split(currentVersion, ".")
-	if len(parts) != 3 {
-		return nil, fmt.Errorf("invalid semver format: %s", currentVersion)
+	current, err := semver.Parse(currentVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse current version: %w", err)
 	}
 
-	var major, minor, patch int
-	fmt.Sscanf(parts[0], "%d", &major)
-	fmt.Sscanf(parts[1], "%d", &minor)
-	fmt.Sscanf(parts[2], "%d", &patch)
-
-	switch bump {
-	case "major":
-		major++
-		minor = 0
-		patch = 0
-	case "minor":
-		minor++
-		patch = 0
-	case "patch":
-		patch++
-	default:
-		return nil, fmt.Errorf("invalid bump type: %s (must be major, minor, or patch)", bump)
+	next, err := current.Bump(semver.Level(bump), pre)
+	if err != nil {
+		return nil, err
 	}
 
-	newVersion := fmt.Sprintf("%d.%d.%d", major, minor, patch)
+	newVersion := next.String()
 
-	// Update Cargo.toml using sed in container
-	updated := dag.Container().
-		From("alpine:latest").
-		WithMountedDirectory("/app", source).
-		WithWorkdir("/app").
-		WithExec([]string{
-			"sed", "-i",
-			fmt.Sprintf(`s/version = "%s"/version = "%s"/g`, currentVersion, newVersion),
-			"Cargo.toml",
-		}).
-		Directory("/app")
-
-	return updated, nil
+	if scope == "root" {
+		pkgName, err := m.rootPackageName(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		return m.manifestContainer(source).
+			WithExec([]string{"cargo", "set-version", "-p", pkgName, newVersion}).
+			Directory("/app"), nil
+	}
+
+	return m.SetVersion(source, newVersion), nil
 }
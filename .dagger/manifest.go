@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"dagger/forge/internal/dagger"
+)
+
+// manifestContainer is a Rust container with taplo (TOML read/edit) and
+// cargo-edit (workspace-aware `cargo set-version`) installed, so manifest
+// reads and mutations go through real TOML parsing instead of regex/sed.
+func (m *Forge) manifestContainer(source *dagger.Directory) *dagger.Container {
+	return dag.Container().
+		From("rust:1.83-slim").
+		WithMountedDirectory("/app", source).
+		WithWorkdir("/app").
+		WithMountedCache("/root/.cargo/registry", dag.CacheVolume("cargo-registry")).
+		WithMountedCache("/root/.cargo/bin", dag.CacheVolume("cargo-manifest-tools")).
+		WithEnvVariable("CARGO_HOME", "/root/.cargo").
+		WithExec([]string{"cargo", "install", "--locked", "cargo-edit", "taplo-cli"})
+}
+
+// taploGet reads a dotted TOML key from path using taplo, returning the
+// raw (still-quoted) JSON-encoded value, or "" if the key isn't present.
+// taplo writes its errors to stderr (stdout is never a reliable signal,
+// since a real failure also leaves it empty), so a missing key is
+// distinguished from malformed TOML, a missing file, or any other
+// failure by matching taplo's own "not found" wording in stderr rather
+// than by stdout emptiness.
+func (m *Forge) taploGet(ctx context.Context, source *dagger.Directory, path, key string) (string, error) {
+	ctr := m.manifestContainer(source).
+		WithExec([]string{"taplo", "get", "-f", path, "-o", "json", key}, dagger.ContainerWithExecOpts{
+			Expect: dagger.ReturnTypeAny,
+		})
+
+	code, err := ctr.ExitCode(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to run taplo get %s: %w", key, err)
+	}
+
+	if code == 0 {
+		out, err := ctr.Stdout(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to run taplo get %s: %w", key, err)
+		}
+		return strings.TrimSpace(out), nil
+	}
+
+	stderr, err := ctr.Stderr(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to run taplo get %s: %w", key, err)
+	}
+	stderr = strings.TrimSpace(stderr)
+
+	if strings.Contains(strings.ToLower(stderr), "not found") {
+		return "", nil
+	}
+
+	return "", fmt.Errorf("taplo get %s exited %d: %s", key, code, stderr)
+}
+
+// ReadVersion reads the effective package version from Cargo.toml,
+// preferring `[workspace.package] version` and falling back to a root
+// `[package] version`, the same precedence cargo itself uses for
+// `version.workspace = true` inheritance.
+func (m *Forge) ReadVersion(ctx context.Context, source *dagger.Directory) (string, error) {
+	raw, err := m.taploGet(ctx, source, "Cargo.toml", "workspace.package.version")
+	if err != nil {
+		return "", err
+	}
+	if raw == "" {
+		raw, err = m.taploGet(ctx, source, "Cargo.toml", "package.version")
+		if err != nil {
+			return "", err
+		}
+	}
+	if raw == "" {
+		return "", fmt.Errorf("failed to read version from Cargo.toml")
+	}
+
+	var version string
+	if err := json.Unmarshal([]byte(raw), &version); err != nil {
+		return "", fmt.Errorf("failed to parse version from Cargo.toml: %w", err)
+	}
+	return version, nil
+}
+
+// rootPackageName reads the `[package] name` declared in Cargo.toml, i.e.
+// the root crate's own name as opposed to any workspace member's.
+func (m *Forge) rootPackageName(ctx context.Context, source *dagger.Directory) (string, error) {
+	raw, err := m.taploGet(ctx, source, "Cargo.toml", "package.name")
+	if err != nil {
+		return "", err
+	}
+	if raw == "" {
+		return "", fmt.Errorf("failed to read package name from Cargo.toml")
+	}
+
+	var name string
+	if err := json.Unmarshal([]byte(raw), &name); err != nil {
+		return "", fmt.Errorf("failed to parse package name from Cargo.toml: %w", err)
+	}
+	return name, nil
+}
+
+// ListWorkspaceMembers returns the `[workspace.members]` path globs
+// declared in Cargo.toml, or an empty slice for a non-workspace manifest.
+func (m *Forge) ListWorkspaceMembers(ctx context.Context, source *dagger.Directory) ([]string, error) {
+	raw, err := m.taploGet(ctx, source, "Cargo.toml", "workspace.members")
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	var members []string
+	if err := json.Unmarshal([]byte(raw), &members); err != nil {
+		return nil, fmt.Errorf("failed to parse workspace members from Cargo.toml: %w", err)
+	}
+	return members, nil
+}
+
+// SetVersion rewrites Cargo.toml (and every workspace member's manifest)
+// to version v, via `cargo set-version` so workspace inheritance,
+// comments, and formatting are preserved.
+func (m *Forge) SetVersion(source *dagger.Directory, v string) *dagger.Directory {
+	return m.manifestContainer(source).
+		WithExec([]string{"cargo", "set-version", "--workspace", v}).
+		Directory("/app")
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dagger/forge/internal/dagger"
+)
+
+// cosignImage is the container image used to run cosign for signing and
+// attesting release images.
+const cosignImage = "gcr.io/projectsigstore/cosign:v2.4.1"
+
+// sbomFormats maps a Sbom format name to the syft output format and the
+// sidecar file extension it's conventionally published under.
+var sbomFormats = map[string]struct {
+	syftFormat string
+	ext        string
+}{
+	"spdx-json":      {"spdx-json", "spdx.json"},
+	"cyclonedx-json": {"cyclonedx-json", "cdx.json"},
+}
+
+// Sbom generates a software bill of materials for the built release
+// container by running syft against its image tarball.
+func (m *Forge) Sbom(
+	ctx context.Context,
+	source *dagger.Directory,
+	// SBOM format: "spdx-json" (default) or "cyclonedx-json"
+	// +optional
+	// +default="spdx-json"
+	format string,
+) (*dagger.File, error) {
+	if format == "" {
+		format = "spdx-json"
+	}
+	f, ok := sbomFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("invalid sbom format %q: must be spdx-json or cyclonedx-json", format)
+	}
+
+	// AsTarball writes an OCI-layout tarball (index.json/oci-layout), not
+	// the legacy docker-save layout, so it must be read back as
+	// oci-archive, not docker-archive.
+	image := m.BuildContainer(ctx, source).AsTarball()
+	out := "/tmp/sbom." + f.ext
+
+	return dag.Container().
+		From("anchore/syft:latest").
+		WithMountedCache("/root/.cache/syft", dag.CacheVolume("syft-cache")).
+		WithMountedFile("/tmp/image.tar", image).
+		WithExec([]string{
+			"syft", "oci-archive:/tmp/image.tar",
+			"-o", f.syftFormat + "=" + out,
+		}).
+		File(out), nil
+}
+
+// BinarySbom generates a software bill of materials for a single target's
+// built release binary by running syft against it directly, rather than
+// against a built container image.
+func (m *Forge) BinarySbom(
+	ctx context.Context,
+	source *dagger.Directory,
+	// Target triple to build and scan (e.g. x86_64-unknown-linux-gnu)
+	// +optional
+	// +default="x86_64-unknown-linux-gnu"
+	target string,
+	// SBOM format: "spdx-json" (default) or "cyclonedx-json"
+	// +optional
+	// +default="spdx-json"
+	format string,
+) (*dagger.File, error) {
+	if target == "" {
+		target = "x86_64-unknown-linux-gnu"
+	}
+	if format == "" {
+		format = "spdx-json"
+	}
+	f, ok := sbomFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("invalid sbom format %q: must be spdx-json or cyclonedx-json", format)
+	}
+
+	binary := m.BuildRelease(ctx, source, target)
+	out := "/tmp/sbom." + f.ext
+
+	return dag.Container().
+		From("anchore/syft:latest").
+		WithMountedCache("/root/.cache/syft", dag.CacheVolume("syft-cache")).
+		WithMountedFile("/tmp/binary", binary).
+		WithExec([]string{
+			"syft", "/tmp/binary",
+			"-o", f.syftFormat + "=" + out,
+		}).
+		File(out), nil
+}
+
+// BinarySbomsAll generates BinarySbom for every target in crossTargets,
+// collecting them into a directory keyed by each target's sidecar name
+// (e.g. "forge-darwin-arm64.spdx.json"): the target's OS, arch, and suffix,
+// like distName but without the version or the binary's extension.
+func (m *Forge) BinarySbomsAll(
+	ctx context.Context,
+	source *dagger.Directory,
+	// SBOM format: "spdx-json" (default) or "cyclonedx-json"
+	// +optional
+	// +default="spdx-json"
+	format string,
+) (*dagger.Directory, error) {
+	if format == "" {
+		format = "spdx-json"
+	}
+	f, ok := sbomFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("invalid sbom format %q: must be spdx-json or cyclonedx-json", format)
+	}
+
+	out := dag.Directory()
+	for _, t := range crossTargets {
+		sbom, err := m.BinarySbom(ctx, source, t.Triple, format)
+		if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("forge-%s-%s%s.%s", t.OS, t.Arch, t.Suffix, f.ext)
+		out = out.WithFile(name, sbom)
+	}
+
+	return out, nil
+}
+
+// registryHost extracts the registry host from an image reference, e.g.
+// "ghcr.io/jedarden/forge@sha256:..." -> "ghcr.io".
+func registryHost(image string) string {
+	host := strings.SplitN(image, "/", 2)[0]
+	if host != "localhost" && !strings.ContainsAny(host, ".:") {
+		return "docker.io"
+	}
+	return host
+}
+
+// cosignContainer is the base container used for cosign sign/attest, with
+// its cache and credentials wired up.
+func cosignContainer(
+	image string,
+	// +optional
+	key *dagger.Secret,
+	// +optional
+	registryUsername string,
+	// +optional
+	registryPassword *dagger.Secret,
+) *dagger.Container {
+	container := dag.Container().
+		From(cosignImage).
+		WithMountedCache("/root/.sigstore", dag.CacheVolume("cosign-cache")).
+		WithEnvVariable("COSIGN_YES", "true")
+
+	if registryUsername != "" && registryPassword != nil {
+		container = container.
+			WithEnvVariable("COSIGN_REGISTRY_USERNAME", registryUsername).
+			WithSecretVariable("COSIGN_REGISTRY_PASSWORD", registryPassword).
+			WithExec([]string{
+				"sh", "-c",
+				fmt.Sprintf("cosign login %s -u \"$COSIGN_REGISTRY_USERNAME\" -p \"$COSIGN_REGISTRY_PASSWORD\"", registryHost(image)),
+			})
+	}
+
+	if key != nil {
+		container = container.WithSecretVariable("COSIGN_KEY_CONTENTS", key)
+	}
+
+	return container
+}
+
+// Sign signs an image digest with cosign: keyless OIDC by default, or
+// key-based when key is provided. registryUsername/registryPassword
+// authenticate the push of the resulting signature.
+func (m *Forge) Sign(
+	ctx context.Context,
+	// Image reference to sign, as returned by Publish (registry/repo@sha256:...)
+	image string,
+	// Cosign private key; omit for keyless OIDC signing
+	// +optional
+	key *dagger.Secret,
+	// +optional
+	registryUsername string,
+	// +optional
+	registryPassword *dagger.Secret,
+) (string, error) {
+	container := cosignContainer(image, key, registryUsername, registryPassword)
+
+	cmd := []string{"cosign", "sign", image}
+	if key != nil {
+		cmd = append(cmd, "--key", "env://COSIGN_KEY_CONTENTS")
+	}
+
+	return container.WithExec(cmd).Stdout(ctx)
+}
+
+// Attest attaches sbom to image as a cosign in-toto attestation, signed the
+// same way as Sign.
+func (m *Forge) Attest(
+	ctx context.Context,
+	image string,
+	sbom *dagger.File,
+	// SBOM predicate type passed to `cosign attest --type`
+	// +optional
+	// +default="spdxjson"
+	predicateType string,
+	// +optional
+	key *dagger.Secret,
+	// +optional
+	registryUsername string,
+	// +optional
+	registryPassword *dagger.Secret,
+) (string, error) {
+	if predicateType == "" {
+		predicateType = "spdxjson"
+	}
+
+	container := cosignContainer(image, key, registryUsername, registryPassword).
+		WithMountedFile("/tmp/predicate.json", sbom)
+
+	cmd := []string{"cosign", "attest", "--predicate", "/tmp/predicate.json", "--type", predicateType, image}
+	if key != nil {
+		cmd = append(cmd, "--key", "env://COSIGN_KEY_CONTENTS")
+	}
+
+	return container.WithExec(cmd).Stdout(ctx)
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestRegistryHost(t *testing.T) {
+	cases := []struct {
+		image string
+		want  string
+	}{
+		{"ghcr.io/jedarden/forge@sha256:abc", "ghcr.io"},
+		{"docker.io/jedarden/forge:latest", "docker.io"},
+		{"jedarden/forge:latest", "docker.io"},
+		{"localhost/forge:latest", "localhost"},
+		{"localhost:5000/forge:latest", "localhost:5000"},
+		{"registry.internal:5000/forge@sha256:abc", "registry.internal:5000"},
+	}
+
+	for _, c := range cases {
+		if got := registryHost(c.image); got != c.want {
+			t.Errorf("registryHost(%q) = %q, want %q", c.image, got, c.want)
+		}
+	}
+}
+
+func TestSbomFormats(t *testing.T) {
+	cases := []struct {
+		format     string
+		wantSyft   string
+		wantExt    string
+		wantExists bool
+	}{
+		{"spdx-json", "spdx-json", "spdx.json", true},
+		{"cyclonedx-json", "cyclonedx-json", "cdx.json", true},
+		{"syft-json", "", "", false},
+	}
+
+	for _, c := range cases {
+		got, ok := sbomFormats[c.format]
+		if ok != c.wantExists {
+			t.Errorf("sbomFormats[%q] ok = %v, want %v", c.format, ok, c.wantExists)
+			continue
+		}
+		if ok && (got.syftFormat != c.wantSyft || got.ext != c.wantExt) {
+			t.Errorf("sbomFormats[%q] = %+v, want {%q %q}", c.format, got, c.wantSyft, c.wantExt)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestBumpTrailingNumber(t *testing.T) {
+	cases := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{in: "rc.1", want: "rc.2", wantOK: true},
+		{in: "1", want: "2", wantOK: true},
+		{in: "alpha", wantOK: false},
+		{in: "alpha.beta", wantOK: false},
+	}
+
+	for _, c := range cases {
+		got, ok := bumpTrailingNumber(c.in)
+		if ok != c.wantOK {
+			t.Errorf("bumpTrailingNumber(%q) ok = %v, want %v", c.in, ok, c.wantOK)
+			continue
+		}
+		if ok && got != c.want {
+			t.Errorf("bumpTrailingNumber(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestIncrementVersion(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{in: "1.2.3", want: "1.2.4"},
+		{in: "1.2.3-rc.1", want: "1.2.3-rc.2"},
+		{in: "1.2.3-alpha", want: "1.2.3-alpha"},
+		{in: "not-a-version", want: "not-a-version"},
+	}
+
+	for _, c := range cases {
+		if got := incrementVersion(c.in); got != c.want {
+			t.Errorf("incrementVersion(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}